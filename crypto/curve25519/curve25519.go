@@ -0,0 +1,124 @@
+// Package curve25519 implements X25519 Diffie-Hellman key agreement on
+// Curve25519, the Montgomery curve birationally equivalent to the
+// twisted Edwards curve implemented by dissent/crypto/edwards/ed25519.
+// The ladder reuses that package's field arithmetic directly, rather
+// than pulling in a separate implementation.
+package curve25519
+
+import (
+	"crypto/cipher"
+
+	"dissent/crypto/edwards/ed25519"
+)
+
+// Sizes, in bytes, of an X25519 scalar and encoded u-coordinate.
+const (
+	ScalarSize = 32
+	PointSize  = 32
+)
+
+// PublicKey is an encoded Curve25519 u-coordinate.
+type PublicKey [PointSize]byte
+
+// PrivateKey is a clamped Curve25519 scalar.
+type PrivateKey [ScalarSize]byte
+
+// basePoint is the standard Curve25519 base point, u = 9.
+var basePoint = PublicKey{9}
+
+// GenerateKey generates a fresh X25519 keypair, reading the scalar from
+// rand and clamping it per RFC 7748.
+func GenerateKey(rand cipher.Stream) (PublicKey, PrivateKey) {
+	var priv PrivateKey
+	rand.XORKeyStream(priv[:], priv[:])
+	clamp(&priv)
+
+	return ladder(&priv, &basePoint), priv
+}
+
+// Bytes returns the encoded u-coordinate of pub.
+func (pub *PublicKey) Bytes() []byte {
+	return pub[:]
+}
+
+// ECDH computes the shared Curve25519 secret between priv and a peer's
+// public key, i.e. the u-coordinate of priv·peer.
+func (priv *PrivateKey) ECDH(peer PublicKey) [PointSize]byte {
+	return ladder(priv, &peer)
+}
+
+// clamp applies the standard X25519 scalar clamping:
+// k[0] &= 248; k[31] &= 127; k[31] |= 64.
+func clamp(k *PrivateKey) {
+	k[0] &= 248
+	k[31] &= 127
+	k[31] |= 64
+}
+
+// feCSwap conditionally swaps a and b in constant time, if swap == 1.
+func feCSwap(a, b *ed25519.FieldElement, swap int32) {
+	t := *a
+	ed25519.FeCMove(a, b, swap)
+	ed25519.FeCMove(b, &t, swap)
+}
+
+// a24 is (486662-2)/4, the Montgomery curve constant used by the ladder.
+var a24 = ed25519.FieldElement{121665}
+
+// ladder runs the Montgomery ladder (RFC 7748 section 5) to multiply
+// the u-coordinate in u by the clamped scalar in priv, using the field
+// arithmetic from the ed25519 package.
+func ladder(priv *PrivateKey, u *PublicKey) PublicKey {
+	var in [32]byte
+	copy(in[:], u[:])
+	in[31] &= 0x7f // mask the high bit of the u-coordinate (RFC 7748 section 5)
+
+	var x1 ed25519.FieldElement
+	ed25519.FeFromBytes(&x1, &in)
+
+	x2 := ed25519.FieldElement{1}
+	var z2 ed25519.FieldElement
+	x3 := x1
+	z3 := ed25519.FieldElement{1}
+
+	var swap int32
+	for pos := 254; pos >= 0; pos-- {
+		b := int32((priv[pos/8] >> uint(pos&7)) & 1)
+		swap ^= b
+		feCSwap(&x2, &x3, swap)
+		feCSwap(&z2, &z3, swap)
+		swap = b
+
+		var a, aa, bb, bb2, e, c, d, da, cb, tmp0, tmp1 ed25519.FieldElement
+		ed25519.FeAdd(&a, &x2, &z2)
+		ed25519.FeSquare(&aa, &a)
+		ed25519.FeSub(&bb2, &x2, &z2)
+		ed25519.FeSquare(&bb, &bb2)
+		ed25519.FeSub(&e, &aa, &bb)
+		ed25519.FeAdd(&c, &x3, &z3)
+		ed25519.FeSub(&d, &x3, &z3)
+		ed25519.FeMul(&da, &d, &a)
+		ed25519.FeMul(&cb, &c, &bb2)
+
+		ed25519.FeAdd(&tmp0, &da, &cb)
+		ed25519.FeSquare(&x3, &tmp0)
+		ed25519.FeSub(&tmp1, &da, &cb)
+		ed25519.FeSquare(&tmp1, &tmp1)
+		ed25519.FeMul(&z3, &x1, &tmp1)
+
+		ed25519.FeMul(&x2, &aa, &bb)
+		ed25519.FeMul(&tmp0, &a24, &e)
+		ed25519.FeAdd(&tmp0, &aa, &tmp0)
+		ed25519.FeMul(&z2, &e, &tmp0)
+	}
+	feCSwap(&x2, &x3, swap)
+	feCSwap(&z2, &z3, swap)
+
+	var zInv ed25519.FieldElement
+	ed25519.FeInvert(&zInv, &z2)
+	ed25519.FeMul(&x2, &x2, &zInv)
+
+	var outBytes [32]byte
+	ed25519.FeToBytes(&outBytes, &x2)
+	return PublicKey(outBytes)
+}
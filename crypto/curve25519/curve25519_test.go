@@ -0,0 +1,67 @@
+package curve25519
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+)
+
+// newTestStream returns a deterministic cipher.Stream keyed by seed, so
+// that key generation in these tests is reproducible.
+func newTestStream(seed byte) cipher.Stream {
+	key := make([]byte, 16)
+	key[0] = seed
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic(err)
+	}
+	return cipher.NewCTR(block, make([]byte, 16))
+}
+
+// TestECDHAgreement checks that two parties who exchange public keys
+// generated by GenerateKey arrive at the same shared secret.
+func TestECDHAgreement(t *testing.T) {
+	alicePub, alicePriv := GenerateKey(newTestStream(1))
+	bobPub, bobPriv := GenerateKey(newTestStream(2))
+
+	aliceShared := alicePriv.ECDH(bobPub)
+	bobShared := bobPriv.ECDH(alicePub)
+	if aliceShared != bobShared {
+		t.Fatalf("Alice and Bob computed different shared secrets: %x != %x", aliceShared, bobShared)
+	}
+}
+
+// TestECDHDistinctKeys checks that two different private keys agreeing
+// with the same peer produce different shared secrets.
+func TestECDHDistinctKeys(t *testing.T) {
+	peerPub, _ := GenerateKey(newTestStream(3))
+	_, priv1 := GenerateKey(newTestStream(4))
+	_, priv2 := GenerateKey(newTestStream(5))
+
+	shared1 := priv1.ECDH(peerPub)
+	shared2 := priv2.ECDH(peerPub)
+	if bytes.Equal(shared1[:], shared2[:]) {
+		t.Fatal("distinct private keys produced the same shared secret")
+	}
+}
+
+// TestClampSetsRequiredBits checks that clamp enforces the fixed bits
+// RFC 7748 requires of an X25519 scalar.
+func TestClampSetsRequiredBits(t *testing.T) {
+	var k PrivateKey
+	for i := range k {
+		k[i] = 0xff
+	}
+	clamp(&k)
+
+	if k[0]&0x07 != 0 {
+		t.Fatalf("low 3 bits of k[0] not cleared: %#x", k[0])
+	}
+	if k[31]&0x80 != 0 {
+		t.Fatalf("high bit of k[31] not cleared: %#x", k[31])
+	}
+	if k[31]&0x40 == 0 {
+		t.Fatalf("bit 254 of k[31] not set: %#x", k[31])
+	}
+}
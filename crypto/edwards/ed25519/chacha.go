@@ -0,0 +1,47 @@
+package ed25519
+
+import (
+	"crypto/cipher"
+	"hash"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/chacha20"
+
+	"dissent/crypto"
+)
+
+// chachaBlake2bSuite is a ciphersuite built entirely from non-NIST
+// primitives, for applications that would rather not depend on AES or
+// SHA-2 at all.
+type chachaBlake2bSuite struct {
+	Curve
+}
+
+// BLAKE2b-256 hash function
+func (s *chachaBlake2bSuite) HashLen() int { return 32 }
+func (s *chachaBlake2bSuite) Hash() hash.Hash {
+	h, err := blake2b.New256(nil)
+	if err != nil {
+		panic("can't instantiate BLAKE2b-256: " + err.Error())
+	}
+	return h
+}
+
+// ChaCha20 stream cipher, 256-bit key
+func (s *chachaBlake2bSuite) KeyLen() int { return 32 }
+func (s *chachaBlake2bSuite) Stream(key []byte) cipher.Stream {
+	// Callers derive a fresh key per stream, so a zero nonce is safe
+	// here, the same way the AES suite in point.go reuses a zero IV.
+	nonce := make([]byte, chacha20.NonceSize)
+	str, err := chacha20.NewUnauthenticatedCipher(key, nonce)
+	if err != nil {
+		panic("can't instantiate ChaCha20: " + err.Error())
+	}
+	return str
+}
+
+// Ciphersuite based on ChaCha20, BLAKE2b, and the Ed25519 curve, for
+// applications that distrust NIST primitives.
+func newChaCha20BLAKE2bEd25519() crypto.Suite {
+	return new(chachaBlake2bSuite)
+}
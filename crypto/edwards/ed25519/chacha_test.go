@@ -0,0 +1,38 @@
+package ed25519
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestChaChaBlake2bSuite checks the Stream/Hash behavior of the
+// ChaCha20/BLAKE2b suite: Stream must be a deterministic function of
+// its key, distinct keys must produce distinct keystreams, and Hash
+// must report the hash length it advertises.
+func TestChaChaBlake2bSuite(t *testing.T) {
+	suite := newChaCha20BLAKE2bEd25519().(*chachaBlake2bSuite)
+
+	key1 := make([]byte, suite.KeyLen())
+	key1[0] = 1
+	key2 := make([]byte, suite.KeyLen())
+	key2[0] = 2
+
+	out1a := make([]byte, 32)
+	suite.Stream(key1).XORKeyStream(out1a, out1a)
+	out1b := make([]byte, 32)
+	suite.Stream(key1).XORKeyStream(out1b, out1b)
+	if !bytes.Equal(out1a, out1b) {
+		t.Fatal("Stream is not a deterministic function of its key")
+	}
+
+	out2 := make([]byte, 32)
+	suite.Stream(key2).XORKeyStream(out2, out2)
+	if bytes.Equal(out1a, out2) {
+		t.Fatal("distinct keys produced the same keystream")
+	}
+
+	h := suite.Hash()
+	if h.Size() != suite.HashLen() {
+		t.Fatalf("Hash().Size() = %d, want HashLen() = %d", h.Size(), suite.HashLen())
+	}
+}
@@ -0,0 +1,144 @@
+package ed25519
+
+import (
+	"crypto/elliptic"
+	"math/big"
+)
+
+// edwardsA and edwardsD are the Ed25519 twisted Edwards curve
+// parameters a = -1 and d = -121665/121666 mod p, as big.Ints for use
+// by the affine adapter below.
+var one = big.NewInt(1)
+var edwardsA = big.NewInt(-1)
+var edwardsD, _ = new(big.Int).SetString(
+	"37095705934669439343138083508754565189542113879843219016388"+
+		"785533085940283555", 10)
+
+// ellipticParams holds the Ed25519 group parameters in the form
+// expected by crypto/elliptic: P = 2^255-19, N the prime group order,
+// and (Gx,Gy) the standard base point.
+var ellipticParams *elliptic.CurveParams
+
+func init() {
+	p := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	n, _ := new(big.Int).SetString(
+		"7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+	gx, _ := new(big.Int).SetString(
+		"15112221349535400772501151409588531511454012693041857206046113283949847762202", 10)
+	gy, _ := new(big.Int).SetString(
+		"46316835694926478169428394003475163141307993866256225615783033603165251855960", 10)
+
+	ellipticParams = &elliptic.CurveParams{
+		Name:    "Ed25519",
+		BitSize: 256,
+		P:       p,
+		N:       n,
+		Gx:      gx,
+		Gy:      gy,
+	}
+}
+
+// ellipticCurve adapts the Ed25519 twisted Edwards group to the
+// standard library's crypto/elliptic.Curve interface, so code written
+// against crypto/elliptic can operate on affine (x,y) Ed25519 points.
+type ellipticCurve struct {
+	params *elliptic.CurveParams
+}
+
+// EllipticCurve returns a crypto/elliptic.Curve backed by the Ed25519
+// group. Internally, Add/Double/ScalarMult/ScalarBaseMult convert the
+// affine (x,y) coordinates to extendedGroupElement (Z=1, T=x*y), run
+// the existing extended-coordinate arithmetic, and normalize the result
+// back to affine via x = X/Z, y = Y/Z.
+func EllipticCurve() elliptic.Curve {
+	return &ellipticCurve{params: ellipticParams}
+}
+
+func (c *ellipticCurve) Params() *elliptic.CurveParams {
+	return c.params
+}
+
+// IsOnCurve checks the twisted Edwards equation
+// -x^2 + y^2 ≡ 1 + d·x^2·y^2 (mod p), and rejects the identity (0,1),
+// since callers of this adapter expect prime-order semantics.
+func (c *ellipticCurve) IsOnCurve(x, y *big.Int) bool {
+	if x.Sign() == 0 && y.Cmp(one) == 0 {
+		return false
+	}
+
+	p := c.params.P
+	xx := new(big.Int).Mod(new(big.Int).Mul(x, x), p)
+	yy := new(big.Int).Mod(new(big.Int).Mul(y, y), p)
+
+	lhs := new(big.Int).Mod(new(big.Int).Add(new(big.Int).Mul(edwardsA, xx), yy), p)
+
+	rhs := new(big.Int).Mul(edwardsD, xx)
+	rhs.Mul(rhs, yy)
+	rhs.Add(rhs, one)
+	rhs.Mod(rhs, p)
+
+	return lhs.Cmp(rhs) == 0
+}
+
+func (c *ellipticCurve) Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	P1 := affineToExtended(x1, y1)
+	P2 := affineToExtended(x2, y2)
+
+	var cached cachedGroupElement
+	var sum completedGroupElement
+	P2.ToCached(&cached)
+	sum.Add(&P1, &cached)
+
+	var R extendedGroupElement
+	sum.ToExtended(&R)
+	return extendedToAffine(&R)
+}
+
+func (c *ellipticCurve) Double(x1, y1 *big.Int) (*big.Int, *big.Int) {
+	return c.Add(x1, y1, x1, y1)
+}
+
+func (c *ellipticCurve) ScalarMult(x1, y1 *big.Int, k []byte) (*big.Int, *big.Int) {
+	P1 := affineToExtended(x1, y1)
+	s := bigToLE(new(big.Int).SetBytes(k))
+
+	var R extendedGroupElement
+	geScalarMult(&R, &s, &P1)
+	return extendedToAffine(&R)
+}
+
+func (c *ellipticCurve) ScalarBaseMult(k []byte) (*big.Int, *big.Int) {
+	s := bigToLE(new(big.Int).SetBytes(k))
+
+	var R extendedGroupElement
+	geScalarMultBase(&R, &s)
+	return extendedToAffine(&R)
+}
+
+// affineToExtended lifts the affine point (x,y) into extended
+// coordinates, with Z=1 and T=x*y.
+func affineToExtended(x, y *big.Int) extendedGroupElement {
+	xb := bigToLE(new(big.Int).Mod(x, ellipticParams.P))
+	yb := bigToLE(new(big.Int).Mod(y, ellipticParams.P))
+
+	var P extendedGroupElement
+	FeFromBytes(&P.X, &xb)
+	FeFromBytes(&P.Y, &yb)
+	P.Z = FieldElement{1}
+	FeMul(&P.T, &P.X, &P.Y)
+	return P
+}
+
+// extendedToAffine normalizes P back to affine coordinates,
+// x = X/Z, y = Y/Z.
+func extendedToAffine(P *extendedGroupElement) (x, y *big.Int) {
+	var zInv, xAff, yAff FieldElement
+	FeInvert(&zInv, &P.Z)
+	FeMul(&xAff, &P.X, &zInv)
+	FeMul(&yAff, &P.Y, &zInv)
+
+	var xb, yb [32]byte
+	FeToBytes(&xb, &xAff)
+	FeToBytes(&yb, &yAff)
+	return leToBig(xb[:]), leToBig(yb[:])
+}
@@ -0,0 +1,35 @@
+package ed25519
+
+import (
+	"testing"
+)
+
+// TestEllipticRoundTrip checks that a point can be carried from the
+// native point API into the affine elliptic.Curve adapter and back
+// without changing its encoding.
+func TestEllipticRoundTrip(t *testing.T) {
+	c := EllipticCurve().(*ellipticCurve)
+
+	var B point
+	B.Base(nil)
+
+	x, y := extendedToAffine(&B.ge)
+	if !c.IsOnCurve(x, y) {
+		t.Fatal("base point not reported as on-curve by the adapter")
+	}
+
+	P2 := affineToExtended(x, y)
+	var back point
+	back.ge = P2
+	if !back.Equal(&B) {
+		t.Fatal("round-tripping through the affine adapter changed the point")
+	}
+
+	x2, y2 := c.Double(x, y)
+	var doubled point
+	doubled.Add(&B, &B)
+	dx, dy := extendedToAffine(&doubled.ge)
+	if x2.Cmp(dx) != 0 || y2.Cmp(dy) != 0 {
+		t.Fatal("adapter Double disagrees with native point Add(B,B)")
+	}
+}
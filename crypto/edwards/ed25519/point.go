@@ -11,26 +11,23 @@
 // described in the Ed25519 paper, this implementation generally performs
 // extremely well, typically comparable to native C implementations.
 // The tradeoff is that this code is completely specialized to a single curve.
-// 
 package ed25519
 
 import (
 	//"fmt"
-	"hash"
-	"errors"
 	"crypto/aes"
-	"encoding/hex"
 	"crypto/cipher"
 	"crypto/sha256"
 	"dissent/crypto"
+	"encoding/hex"
+	"errors"
+	"hash"
 )
 
-
 type point struct {
 	ge extendedGroupElement
 }
 
-
 func (P *point) String() string {
 	var b [32]byte
 	P.ge.ToBytes(&b)
@@ -59,10 +56,10 @@ func (P *point) Equal(P2 crypto.Point) bool {
 
 	// XXX better to test equality without normalizing extended coords
 
-	var b1,b2 [32]byte
+	var b1, b2 [32]byte
 	P.ge.ToBytes(&b1)
 	P2.(*point).ge.ToBytes(&b2)
-	for i := range(b1) {
+	for i := range b1 {
 		if b1[i] != b2[i] {
 			return false
 		}
@@ -88,10 +85,10 @@ func (P *point) Base(rand cipher.Stream) crypto.Point {
 		P.ge = baseext
 	} else {
 		for {
-			P.Pick(nil, rand)	// pick a random point
-			P.Mul(P, cofactor)	// multiply by Ed25519 cofactor
+			P.Pick(nil, rand)  // pick a random point
+			P.Mul(P, cofactor) // multiply by Ed25519 cofactor
 			if !P.Equal(pzero) {
-				break		// got one
+				break // got one
 			}
 			// retry
 		}
@@ -117,30 +114,30 @@ func (P *point) Pick(data []byte, rand cipher.Stream) (crypto.Point, []byte) {
 	for {
 		// Pick a random point, with optional embedded data
 		var b [32]byte
-		rand.XORKeyStream(b[:],b[:])
+		rand.XORKeyStream(b[:], b[:])
 		if data != nil {
-			b[0] = byte(dl)		// Encode length in low 8 bits
-			copy(b[1:1+dl],data)	// Copy in data to embed
+			b[0] = byte(dl)       // Encode length in low 8 bits
+			copy(b[1:1+dl], data) // Copy in data to embed
 		}
-		if P.ge.FromBytes(b[:]) {	// Try to decode
-			return P,data[dl:]	// success
+		if P.ge.FromBytes(b[:]) { // Try to decode
+			return P, data[dl:] // success
 		}
 		// invalid point, retry
 	}
 }
 
 // Extract embedded data from a point group element
-func (P *point) Data() ([]byte,error) {
+func (P *point) Data() ([]byte, error) {
 	var b [32]byte
 	P.ge.ToBytes(&b)
-	dl := int(b[0])				// extract length byte
+	dl := int(b[0]) // extract length byte
 	if dl > P.PickLen() {
-		return nil,errors.New("invalid embedded data length")
+		return nil, errors.New("invalid embedded data length")
 	}
-	return b[1:1+dl],nil
+	return b[1 : 1+dl], nil
 }
 
-func (P *point) Add(P1,P2 crypto.Point) crypto.Point {
+func (P *point) Add(P1, P2 crypto.Point) crypto.Point {
 	E1 := P1.(*point)
 	E2 := P2.(*point)
 
@@ -156,7 +153,7 @@ func (P *point) Add(P1,P2 crypto.Point) crypto.Point {
 	return P
 }
 
-func (P *point) Sub(P1,P2 crypto.Point) crypto.Point {
+func (P *point) Sub(P1, P2 crypto.Point) crypto.Point {
 	E1 := P1.(*point)
 	E2 := P2.(*point)
 
@@ -179,30 +176,66 @@ func (P *point) Neg(A crypto.Point) crypto.Point {
 	return P
 }
 
+// ToMontgomery returns the Curve25519 (Montgomery) u-coordinate
+// corresponding to this point, via the standard birational map
+// u = (1+y)/(1-y). This lets an Ed25519 public key be reused directly
+// for an X25519 key agreement, without a second keypair.
+func (P *point) ToMontgomery() [32]byte {
+	var zInv, y, one, yPlus1, yMinus1, u FieldElement
+	FeInvert(&zInv, &P.ge.Z)
+	FeMul(&y, &P.ge.Y, &zInv)
+
+	one = FieldElement{1}
+	FeAdd(&yPlus1, &y, &one)
+	FeSub(&yMinus1, &one, &y)
+	FeInvert(&yMinus1, &yMinus1)
+	FeMul(&u, &yPlus1, &yMinus1)
+
+	var out [32]byte
+	FeToBytes(&out, &u)
+	return out
+}
 
-// Multiply point p by scalar s using the repeated doubling method.
-// XXX This is vartime; for our general-purpose Mul operator
-// it would be far preferable for security to do this constant-time.
-func (P *point) Mul(A crypto.Point, s crypto.Secret) crypto.Point {
-
-	// Convert the scalar to fixed-length little-endian form.
+// scalarBytes converts a Secret to fixed-length little-endian form.
+func scalarBytes(s crypto.Secret) [32]byte {
 	sb := s.(*crypto.ModInt).V.Bytes()
-	shi := len(sb)-1
+	shi := len(sb) - 1
 	var a [32]byte
 	for i := range sb {
 		a[shi-i] = sb[i]
 	}
+	return a
+}
+
+// Multiply point p by scalar s in constant time, via the windowed
+// geScalarMult in scalarmult.go, so that Mul is safe to use with secret scalars
+// and/or secret points.
+func (P *point) Mul(A crypto.Point, s crypto.Secret) crypto.Point {
+	a := scalarBytes(s)
 
 	if A == nil {
 		geScalarMultBase(&P.ge, &a)
 	} else {
 		geScalarMult(&P.ge, &a, &A.(*point).ge)
-		//geScalarMultVartime(&P.ge, &a, &A.(*point).ge)
 	}
 
 	return P
 }
 
+// MulVartime behaves like Mul but may take a variable amount of time
+// depending on the scalar and point operands. Only use it when neither
+// is secret, e.g. when verifying a signature against a known public key.
+func (P *point) MulVartime(A crypto.Point, s crypto.Secret) crypto.Point {
+	a := scalarBytes(s)
+
+	if A == nil {
+		geScalarMultBase(&P.ge, &a)
+	} else {
+		geScalarMultVartime(&P.ge, &a, &A.(*point).ge)
+	}
+
+	return P
+}
 
 // Curve represents an Ed25519.
 // There are no parameters and no initialization is required
@@ -237,12 +270,9 @@ func (c *Curve) Point() crypto.Point {
 	return P
 }
 
-
 type suite struct {
 	Curve
-} 
-
-// XXX non-NIST ciphers?
+}
 
 // SHA256 hash function
 func (s *suite) HashLen() int { return sha256.Size }
@@ -257,8 +287,8 @@ func (s *suite) Stream(key []byte) cipher.Stream {
 	if err != nil {
 		panic("can't instantiate AES: " + err.Error())
 	}
-	iv := make([]byte,16)
-	return cipher.NewCTR(aes,iv)
+	iv := make([]byte, 16)
+	return cipher.NewCTR(aes, iv)
 }
 
 // Ciphersuite based on AES-128, SHA-256, and the Ed25519 curve.
@@ -266,4 +296,3 @@ func newAES128SHA256Ed25519() crypto.Suite {
 	suite := new(suite)
 	return suite
 }
-
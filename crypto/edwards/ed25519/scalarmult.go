@@ -0,0 +1,171 @@
+package ed25519
+
+// Constant-time variable-base scalar multiplication, windowed on signed
+// 4-bit digits of the scalar. This replaces the previous vartime
+// doubling-and-adding geScalarMult: a general-purpose Mul operates on
+// secret scalars and/or secret points, so it must not branch or index
+// memory based on their value.
+
+// mulTable holds the cached forms of {A, 2A, ..., 8A} for some point A,
+// the precomputed multiples consulted by each 4-bit window below.
+type mulTable [8]cachedGroupElement
+
+// buildMulTable precomputes {A, 2A, ..., 8A} in cached form. Because
+// the Ed25519 addition formulas are complete, doubling is just adding
+// a point to itself, so no separate doubling formula is needed here.
+func buildMulTable(A *extendedGroupElement) *mulTable {
+	var t mulTable
+	var cachedA cachedGroupElement
+	A.ToCached(&cachedA)
+	t[0] = cachedA
+
+	cur := *A
+	for i := 1; i < 8; i++ {
+		var c completedGroupElement
+		c.Add(&cur, &cachedA)
+		c.ToExtended(&cur)
+		cur.ToCached(&t[i])
+	}
+	return &t
+}
+
+// identityCached is the cached form of the neutral element, used to
+// seed the constant-time table lookup below.
+var identityCached = func() cachedGroupElement {
+	var zero extendedGroupElement
+	zero.Zero()
+	var c cachedGroupElement
+	zero.ToCached(&c)
+	return c
+}()
+
+// cachedCMove sets *t = *u if b == 1, and leaves *t unchanged if b == 0.
+func cachedCMove(t, u *cachedGroupElement, b int32) {
+	FeCMove(&t.yPlusX, &u.yPlusX, b)
+	FeCMove(&t.yMinusX, &u.yMinusX, b)
+	FeCMove(&t.Z, &u.Z, b)
+	FeCMove(&t.T2d, &u.T2d, b)
+}
+
+// cachedNeg negates a cached group element in place, by swapping its
+// (y+x, y-x) halves and negating T2d.
+func cachedNeg(t *cachedGroupElement) {
+	t.yPlusX, t.yMinusX = t.yMinusX, t.yPlusX
+	FeNeg(&t.T2d, &t.T2d)
+}
+
+// ctEqual returns 1 if b == c and 0 otherwise, without branching.
+func ctEqual(b, c int32) int32 {
+	x := uint32(b ^ c)
+	x--
+	return int32(x >> 31)
+}
+
+// ctNegative returns 1 if b < 0 and 0 otherwise, without branching.
+func ctNegative(b int32) int32 {
+	return int32(uint32(b) >> 31)
+}
+
+// selectCached sets *t to b·A in cached form, where table holds the
+// cached multiples of A and b is a signed digit in [-8,8]. Every entry
+// of table is scanned and CMove'd into t regardless of b, and the
+// result is conditionally negated, so the memory access pattern and
+// timing are independent of b.
+func selectCached(t *cachedGroupElement, table *mulTable, b int32) {
+	bNegative := ctNegative(b)
+	bAbs := b - (((-bNegative) & b) << 1)
+
+	*t = identityCached
+	for i := int32(0); i < 8; i++ {
+		cachedCMove(t, &table[i], ctEqual(bAbs, i+1))
+	}
+
+	minusT := *t
+	cachedNeg(&minusT)
+	cachedCMove(t, &minusT, bNegative)
+}
+
+// signedNibbles splits the little-endian scalar s into 64 signed
+// nibbles e[i] ∈ [-8,8], via the standard carry-propagation recoding:
+// carry = (e[i]+8)>>4; e[i] -= carry<<4; e[i+1] += carry.
+func signedNibbles(s *[32]byte) [64]int8 {
+	var e [64]int8
+	for i := 0; i < 32; i++ {
+		e[2*i] = int8(s[i] & 15)
+		e[2*i+1] = int8((s[i] >> 4) & 15)
+	}
+
+	var carry int8
+	for i := 0; i < 63; i++ {
+		e[i] += carry
+		carry = (e[i] + 8) >> 4
+		e[i] -= carry << 4
+	}
+	e[63] += carry
+
+	return e
+}
+
+// geScalarMult sets r = s·A in constant time, using the signed 4-bit
+// windowed method: four doublings followed by one constant-time table
+// lookup and add per nibble, from the most to the least significant.
+func geScalarMult(r *extendedGroupElement, s *[32]byte, A *extendedGroupElement) {
+	table := buildMulTable(A)
+	e := signedNibbles(s)
+
+	r.Zero()
+	for i := 63; i >= 0; i-- {
+		for j := 0; j < 4; j++ {
+			var rc cachedGroupElement
+			var c completedGroupElement
+			r.ToCached(&rc)
+			c.Add(r, &rc) // doubling: P+P via the complete addition law
+			c.ToExtended(r)
+		}
+
+		var t cachedGroupElement
+		selectCached(&t, table, int32(e[i]))
+
+		var c completedGroupElement
+		c.Add(r, &t)
+		c.ToExtended(r)
+	}
+}
+
+// geScalarMultVartime sets r = s·A using the same signed 4-bit windowed
+// method as geScalarMult, but looks up and (if needed) negates the table
+// entry directly instead of scanning the whole table and CMove'ing, since
+// A and s are not secret here: Verify's k·A and k are both derived from
+// public inputs and a signature, so there's nothing to protect by paying
+// for constant-time table lookups.
+func geScalarMultVartime(r *extendedGroupElement, s *[32]byte, A *extendedGroupElement) {
+	table := buildMulTable(A)
+	e := signedNibbles(s)
+
+	r.Zero()
+	for i := 63; i >= 0; i-- {
+		for j := 0; j < 4; j++ {
+			var rc cachedGroupElement
+			var c completedGroupElement
+			r.ToCached(&rc)
+			c.Add(r, &rc) // doubling: P+P via the complete addition law
+			c.ToExtended(r)
+		}
+
+		if e[i] == 0 {
+			continue
+		}
+
+		var t cachedGroupElement
+		if e[i] > 0 {
+			t = table[e[i]-1]
+		} else {
+			t = table[-e[i]-1]
+			cachedNeg(&t)
+		}
+
+		var c completedGroupElement
+		c.Add(r, &t)
+		c.ToExtended(r)
+	}
+}
@@ -0,0 +1,204 @@
+package ed25519
+
+import (
+	"crypto/cipher"
+	"crypto/sha512"
+	"math/big"
+
+	"dissent/crypto"
+)
+
+// Sizes, in bytes, of the standard Ed25519 key and signature encodings.
+const (
+	PublicKeySize  = 32
+	PrivateKeySize = 64
+	SignatureSize  = 64
+)
+
+// PublicKey is an Ed25519 public key: the encoding of A = h·B.
+type PublicKey []byte
+
+// PrivateKey is an Ed25519 private key: the 32-byte seed that generated
+// it, followed by the 32-byte PublicKey derived from that seed.
+type PrivateKey []byte
+
+// Public returns the PublicKey embedded at the end of priv.
+func (priv PrivateKey) Public() PublicKey {
+	pub := make([]byte, PublicKeySize)
+	copy(pub, priv[32:])
+	return PublicKey(pub)
+}
+
+// Seed returns the 32-byte seed priv was generated from.
+func (priv PrivateKey) Seed() []byte {
+	seed := make([]byte, 32)
+	copy(seed, priv[:32])
+	return seed
+}
+
+// GenerateKey generates a fresh Ed25519 keypair, reading the 32-byte
+// seed from rand.
+func GenerateKey(rand cipher.Stream) (PublicKey, PrivateKey) {
+	seed := make([]byte, 32)
+	rand.XORKeyStream(seed, seed)
+	return keyFromSeed(seed)
+}
+
+// keyFromSeed derives the (public, private) keypair for seed,
+// following the standard EdDSA key generation procedure: expand the
+// seed via SHA-512, clamp the low half into the secret scalar h, and
+// compute the public key A = h·B.
+func keyFromSeed(seed []byte) (PublicKey, PrivateKey) {
+	digest := sha512.Sum512(seed)
+	h := clampScalar(digest[:32])
+
+	var hs crypto.ModInt
+	hs.Init(leToBig(h[:]), order)
+
+	var A point
+	A.Mul(nil, &hs)
+	pub := A.Encode()
+
+	priv := make([]byte, PrivateKeySize)
+	copy(priv, seed)
+	copy(priv[32:], pub)
+
+	return PublicKey(pub), PrivateKey(priv)
+}
+
+// clampScalar applies the Ed25519 clamping operation required before a
+// SHA-512 digest half may be used as the signing scalar h:
+// h[0] &= 248, h[31] &= 127, h[31] |= 64.
+func clampScalar(h []byte) [32]byte {
+	var c [32]byte
+	copy(c[:], h)
+	c[0] &= 248
+	c[31] &= 127
+	c[31] |= 64
+	return c
+}
+
+// leToBig interprets the little-endian byte string b as an integer.
+func leToBig(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i, v := range b {
+		be[len(b)-1-i] = v
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+// bigToLE encodes n as a fixed 32-byte little-endian integer.
+func bigToLE(n *big.Int) [32]byte {
+	var out [32]byte
+	b := n.Bytes()
+	for i, v := range b {
+		out[len(b)-1-i] = v
+	}
+	return out
+}
+
+// Sign signs message with privateKey and returns the resulting 64-byte
+// signature R||S, computed per the EdDSA scheme from the Ed25519 paper:
+//
+//	(h, prefix) = SHA-512(seed)
+//	r = SHA-512(prefix || M) mod L,  R = r·B
+//	k = SHA-512(R || A || M) mod L
+//	S = (r + k·h) mod L
+//
+// Hashing is pinned to SHA-512 rather than the suite's configured Hash,
+// since EdDSA's security proof depends on that specific function.
+func Sign(privateKey PrivateKey, message []byte) []byte {
+	digest := sha512.Sum512(privateKey[:32])
+	h := clampScalar(digest[:32])
+	prefix := digest[32:]
+
+	var hs crypto.ModInt
+	hs.Init(leToBig(h[:]), order)
+
+	rDigest := sha512.New()
+	rDigest.Write(prefix)
+	rDigest.Write(message)
+	var rh [64]byte
+	rDigest.Sum(rh[:0])
+
+	var r crypto.ModInt
+	r.Init(leToBig(rh[:]), order)
+
+	var R point
+	R.Mul(nil, &r)
+	encR := R.Encode()
+
+	kDigest := sha512.New()
+	kDigest.Write(encR)
+	kDigest.Write(privateKey[32:])
+	kDigest.Write(message)
+	var kh [64]byte
+	kDigest.Sum(kh[:0])
+
+	var k crypto.ModInt
+	k.Init(leToBig(kh[:]), order)
+
+	var s crypto.ModInt
+	s.Init(big.NewInt(0), order)
+	s.Mul(&k, &hs).Add(&s, &r)
+	sb := bigToLE(&s.V)
+
+	sig := make([]byte, SignatureSize)
+	copy(sig, encR)
+	copy(sig[32:], sb[:])
+	return sig
+}
+
+// Verify reports whether sig is a valid Ed25519 signature by publicKey
+// over message. It recomputes k, then checks that S·B == R + k·A,
+// rejecting sig if S >= L or if the top three bits of sig[63] are set
+// (both of which would indicate a non-canonical, malleable encoding).
+func Verify(publicKey PublicKey, message, sig []byte) bool {
+	if len(publicKey) != PublicKeySize || len(sig) != SignatureSize {
+		return false
+	}
+	if sig[63]&0xe0 != 0 {
+		return false
+	}
+
+	sInt := leToBig(sig[32:])
+	if sInt.Cmp(order) >= 0 {
+		return false
+	}
+
+	var A point
+	if err := A.Decode(publicKey); err != nil {
+		return false
+	}
+	var R point
+	if err := R.Decode(sig[:32]); err != nil {
+		return false
+	}
+
+	h := sha512.New()
+	h.Write(sig[:32])
+	h.Write(publicKey)
+	h.Write(message)
+	var digest [64]byte
+	h.Sum(digest[:0])
+
+	var k crypto.ModInt
+	k.Init(leToBig(digest[:]), order)
+
+	var s crypto.ModInt
+	s.Init(sInt, order)
+
+	// Neither operand below is secret, so the vartime multiply is safe
+	// and faster than the constant-time Mul used for signing. This runs
+	// S·B and k·A as two separate scalar multiplies rather than a single
+	// combined double-scalar-multiply; that's a possible follow-up
+	// optimization, not a correctness issue.
+	var lhs point
+	lhs.MulVartime(nil, &s) // S·B
+
+	var rhs point
+	rhs.MulVartime(&A, &k) // k·A
+	rhs.Add(&R, &rhs)      // R + k·A
+
+	return lhs.Equal(&rhs)
+}
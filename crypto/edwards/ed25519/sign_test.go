@@ -0,0 +1,74 @@
+package ed25519
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// TestSignVerifyRoundTrip checks that a signature produced by Sign
+// validates under Verify, and that tampering with the message,
+// signature, or public key invalidates it.
+func TestSignVerifyRoundTrip(t *testing.T) {
+	var seed [32]byte
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	pub, priv := keyFromSeed(seed[:])
+
+	message := []byte("dissent/crypto ed25519 signature test")
+	sig := Sign(priv, message)
+
+	if !Verify(pub, message, sig) {
+		t.Fatal("valid signature rejected")
+	}
+
+	tamperedMessage := append([]byte{}, message...)
+	tamperedMessage[0] ^= 1
+	if Verify(pub, tamperedMessage, sig) {
+		t.Fatal("signature validated under a different message")
+	}
+
+	tamperedSig := append([]byte{}, sig...)
+	tamperedSig[0] ^= 1
+	if Verify(pub, message, tamperedSig) {
+		t.Fatal("tampered signature validated")
+	}
+
+	var otherSeed [32]byte
+	for i := range otherSeed {
+		otherSeed[i] = byte(i + 1)
+	}
+	otherPub, _ := keyFromSeed(otherSeed[:])
+	if Verify(otherPub, message, sig) {
+		t.Fatal("signature validated under the wrong public key")
+	}
+}
+
+// TestSignKnownVector checks the public key and signature derived from
+// a fixed seed against values independently produced by the standard
+// library's crypto/ed25519, which implements the same EdDSA scheme.
+func TestSignKnownVector(t *testing.T) {
+	seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantPub, err := hex.DecodeString("03a107bff3ce10be1d70dd18e74bc09967e4d6309ba50d5f1ddc8664125531b8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSig, err := hex.DecodeString("7f427b54e429395f7de63dd73ecbb8261d17e9df8f76abd99424bdf57cfe3901d86f4bfb2cb0a24fedf6625859b802a3636fb863c7d060c394ace7d162833e0e")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pub, priv := keyFromSeed(seed)
+	if !bytes.Equal(pub, wantPub) {
+		t.Fatalf("public key = %x, want %x", pub, wantPub)
+	}
+
+	sig := Sign(priv, []byte("dissent/crypto ed25519 signature test"))
+	if !bytes.Equal(sig, wantSig) {
+		t.Fatalf("signature = %x, want %x", sig, wantSig)
+	}
+}
@@ -0,0 +1,599 @@
+// Package ristretto255 implements the Ristretto255 prime-order group,
+// a cofactor-1 quotient of the Ed25519 curve. Ristretto's canonical
+// encode/decode eliminates the cofactor pitfalls that the plain
+// Ed25519 point's Pick/Base retry loops otherwise have to paper over.
+// Point arithmetic is expressed in terms of the field element type
+// from dissent/crypto/edwards/ed25519, so this package adds no field
+// implementation of its own.
+package ristretto255
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"math/big"
+
+	"dissent/crypto"
+	"dissent/crypto/edwards/ed25519"
+)
+
+// p is the Curve25519/Ed25519 field prime, 2^255-19.
+var p = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+// order is the prime order of the Ristretto255 group, the same as the
+// Ed25519 group order L.
+var order, _ = new(big.Int).SetString(
+	"7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+
+// edwardsD is the Ed25519 twisted Edwards curve parameter d, and
+// sqrtM1 is a square root of -1 mod p, both needed by the Ristretto
+// encode/decode below. This package has no Elligator map; Pick samples
+// candidate encodings by rejection instead.
+var edwardsD, _ = new(big.Int).SetString(
+	"37095705934669439343138083508754565189542113879843219016388785533085940283555", 10)
+var sqrtM1, _ = new(big.Int).SetString(
+	"19681161376707505956807079304988542015446066515923890162744021073123829784752", 10)
+
+// invSqrtAMinusD is 1/sqrt(a-d) mod p, with a=-1. Encode's rotation
+// branch needs this extra factor folded into its denominator: the
+// rotated (x,y) are scaled by sqrtM1 relative to the unrotated pair, so
+// the denominator that un-scales them differs from den1 by exactly this
+// constant.
+var invSqrtAMinusD, _ = new(big.Int).SetString(
+	"54469307008909316920995813868745141605393597292927456921205312896311721017578", 10)
+
+var feD ed25519.FieldElement // edwardsD, pre-converted to FieldElement form
+
+func init() {
+	var b [32]byte
+	bigToLEBytes(edwardsD, b[:])
+	ed25519.FeFromBytes(&feD, &b)
+}
+
+// groupElement is an extended-coordinates Ed25519 curve point,
+// expressed using the field arithmetic from the ed25519 package.
+// A Ristretto255 Point wraps one of the (up to four) representatives
+// of its coset.
+type groupElement struct {
+	X, Y, Z, T ed25519.FieldElement
+}
+
+func geIdentity() groupElement {
+	return groupElement{
+		Y: ed25519.FieldElement{1},
+		Z: ed25519.FieldElement{1},
+	}
+}
+
+// geAdd implements the complete extended twisted Edwards addition
+// formulas (a = -1) in terms of the ed25519 package's field ops.
+func geAdd(a, b *groupElement) groupElement {
+	var A, B, C, D, E, F, G, H ed25519.FieldElement
+	var aPlusY, bPlusY ed25519.FieldElement
+
+	ed25519.FeMul(&A, &a.X, &b.X)
+	ed25519.FeMul(&B, &a.Y, &b.Y)
+	ed25519.FeMul(&C, &a.T, &b.T)
+	ed25519.FeMul(&C, &C, &feD)
+	ed25519.FeMul(&D, &a.Z, &b.Z)
+
+	ed25519.FeAdd(&aPlusY, &a.X, &a.Y)
+	ed25519.FeAdd(&bPlusY, &b.X, &b.Y)
+	ed25519.FeMul(&E, &aPlusY, &bPlusY)
+	ed25519.FeSub(&E, &E, &A)
+	ed25519.FeSub(&E, &E, &B)
+
+	ed25519.FeSub(&F, &D, &C)
+	ed25519.FeAdd(&G, &D, &C)
+	ed25519.FeAdd(&H, &A, &B) // H = B - a·A, and a = -1
+
+	var r groupElement
+	ed25519.FeMul(&r.X, &E, &F)
+	ed25519.FeMul(&r.Y, &G, &H)
+	ed25519.FeMul(&r.T, &E, &H)
+	ed25519.FeMul(&r.Z, &F, &G)
+	return r
+}
+
+func geNeg(a *groupElement) groupElement {
+	var r groupElement
+	ed25519.FeNeg(&r.X, &a.X)
+	r.Y = a.Y
+	r.Z = a.Z
+	ed25519.FeNeg(&r.T, &a.T)
+	return r
+}
+
+// geMulTable holds the precomputed multiples {A, 2A, ..., 8A} of some
+// point A, consulted by geMul's windowed scalar multiply below.
+type geMulTable [8]groupElement
+
+func buildGeMulTable(A *groupElement) *geMulTable {
+	var t geMulTable
+	t[0] = *A
+
+	cur := *A
+	for i := 1; i < 8; i++ {
+		cur = geAdd(&cur, A)
+		t[i] = cur
+	}
+	return &t
+}
+
+// geCMove sets *t = *u if b == 1, and leaves *t unchanged if b == 0.
+func geCMove(t, u *groupElement, b int32) {
+	ed25519.FeCMove(&t.X, &u.X, b)
+	ed25519.FeCMove(&t.Y, &u.Y, b)
+	ed25519.FeCMove(&t.Z, &u.Z, b)
+	ed25519.FeCMove(&t.T, &u.T, b)
+}
+
+// ctEqual returns 1 if b == c and 0 otherwise, without branching.
+func ctEqual(b, c int32) int32 {
+	x := uint32(b ^ c)
+	x--
+	return int32(x >> 31)
+}
+
+// ctNegative returns 1 if b < 0 and 0 otherwise, without branching.
+func ctNegative(b int32) int32 {
+	return int32(uint32(b) >> 31)
+}
+
+// geSelect sets *t to b·A in constant time, where table holds the
+// precomputed multiples of A and b is a signed digit in [-8,8]: every entry
+// of table is scanned and CMove'd into t regardless of b, and the
+// result is conditionally negated, so the memory access pattern and
+// timing are independent of b.
+func geSelect(t *groupElement, table *geMulTable, b int32) {
+	bNegative := ctNegative(b)
+	bAbs := b - (((-bNegative) & b) << 1)
+
+	*t = geIdentity()
+	for i := int32(0); i < 8; i++ {
+		geCMove(t, &table[i], ctEqual(bAbs, i+1))
+	}
+
+	minusT := geNeg(t)
+	geCMove(t, &minusT, bNegative)
+}
+
+// signedNibbles splits the little-endian scalar s into 64 signed
+// nibbles e[i] ∈ [-8,8], via the standard carry-propagation recoding:
+// carry = (e[i]+8)>>4; e[i] -= carry<<4; e[i+1] += carry.
+func signedNibbles(s *[32]byte) [64]int8 {
+	var e [64]int8
+	for i := 0; i < 32; i++ {
+		e[2*i] = int8(s[i] & 15)
+		e[2*i+1] = int8((s[i] >> 4) & 15)
+	}
+
+	var carry int8
+	for i := 0; i < 63; i++ {
+		e[i] += carry
+		carry = (e[i] + 8) >> 4
+		e[i] -= carry << 4
+	}
+	e[63] += carry
+
+	return e
+}
+
+// geMul computes s·a in constant time, using the same signed 4-bit
+// windowed method as ed25519/scalarmult.go's geScalarMult: a
+// general-purpose Point.Mul operates on secret scalars, so it must not
+// branch or index memory based on their value the way a simple
+// double-and-add would.
+func geMul(a *groupElement, s *big.Int) groupElement {
+	table := buildGeMulTable(a)
+
+	var rev [32]byte
+	bigToLEBytes(new(big.Int).Mod(s, order), rev[:])
+	e := signedNibbles(&rev)
+
+	r := geIdentity()
+	for i := 63; i >= 0; i-- {
+		for j := 0; j < 4; j++ {
+			r = geAdd(&r, &r)
+		}
+
+		var t groupElement
+		geSelect(&t, table, int32(e[i]))
+		r = geAdd(&r, &t)
+	}
+	return r
+}
+
+// leBytesToBig interprets b as a little-endian integer.
+func leBytesToBig(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i, v := range b {
+		be[len(b)-1-i] = v
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+// bigToLEBytes encodes n into the fixed-size little-endian buffer out.
+func bigToLEBytes(n *big.Int, out []byte) {
+	for i := range out {
+		out[i] = 0
+	}
+	b := n.Bytes()
+	for i, v := range b {
+		out[len(b)-1-i] = v
+	}
+}
+
+func bigFromFe(f *ed25519.FieldElement) *big.Int {
+	var b [32]byte
+	ed25519.FeToBytes(&b, f)
+	return leBytesToBig(b[:])
+}
+
+func feFromBig(n *big.Int) ed25519.FieldElement {
+	var b [32]byte
+	bigToLEBytes(new(big.Int).Mod(n, p), b[:])
+	var f ed25519.FieldElement
+	ed25519.FeFromBytes(&f, &b)
+	return f
+}
+
+func isNegative(n *big.Int) bool {
+	return new(big.Int).Mod(n, p).Bit(0) == 1
+}
+
+func absBig(n *big.Int) *big.Int {
+	if isNegative(n) {
+		return new(big.Int).Mod(new(big.Int).Neg(n), p)
+	}
+	return new(big.Int).Mod(n, p)
+}
+
+// sqrtRatioM1 computes a candidate square root of u/v mod p, returning
+// (root, true) if u/v is a square, and (_, false) otherwise, per the standard Ristretto255 sqrt-ratio procedure. Since
+// p ≡ 5 (mod 8), a candidate root is computed as (u/v)^((p+3)/8) and
+// then corrected by sqrt(-1) if the first guess was wrong. The returned
+// root is always canonicalized to its non-negative representative, as
+// required by the callers below that branch on its sign.
+func sqrtRatioM1(u, v *big.Int) (*big.Int, bool) {
+	vInv := new(big.Int).ModInverse(v, p)
+	if vInv == nil {
+		vInv = big.NewInt(0)
+	}
+	uv := new(big.Int).Mod(new(big.Int).Mul(u, vInv), p)
+
+	exp := new(big.Int).Rsh(new(big.Int).Add(p, big.NewInt(3)), 3) // (p+3)/8
+	r := new(big.Int).Exp(uv, exp, p)
+
+	check := new(big.Int).Mod(new(big.Int).Mul(r, r), p)
+	if check.Cmp(uv) == 0 {
+		return absBig(r), true
+	}
+
+	r2 := new(big.Int).Mod(new(big.Int).Mul(r, sqrtM1), p)
+	check2 := new(big.Int).Mod(new(big.Int).Mul(r2, r2), p)
+	if check2.Cmp(uv) == 0 {
+		return absBig(r2), true
+	}
+
+	return absBig(r), false
+}
+
+// Point implements crypto.Point for the Ristretto255 group.
+type Point struct {
+	ge groupElement
+}
+
+func (P *Point) String() string {
+	return hex.EncodeToString(P.Encode())
+}
+
+func (P *Point) Len() int {
+	return 32
+}
+
+// Encode produces the canonical 32-byte Ristretto255 encoding of P,
+// choosing among the representatives of P's coset so that the same
+// point always serializes to the same bytes.
+func (P *Point) Encode() []byte {
+	X := bigFromFe(&P.ge.X)
+	Y := bigFromFe(&P.ge.Y)
+	Z := bigFromFe(&P.ge.Z)
+	T := bigFromFe(&P.ge.T)
+
+	u1 := new(big.Int).Mod(new(big.Int).Mul(
+		new(big.Int).Add(Z, Y), new(big.Int).Sub(Z, Y)), p)
+	u2 := new(big.Int).Mod(new(big.Int).Mul(X, Y), p)
+	u2Sqr := new(big.Int).Mod(new(big.Int).Mul(u2, u2), p)
+
+	invsqrt, _ := sqrtRatioM1(big.NewInt(1), new(big.Int).Mul(u1, u2Sqr))
+
+	den1 := new(big.Int).Mod(new(big.Int).Mul(invsqrt, u1), p)
+	den2 := new(big.Int).Mod(new(big.Int).Mul(invsqrt, u2), p)
+	zInv := new(big.Int).Mod(new(big.Int).Mul(new(big.Int).Mul(den1, den2), T), p)
+
+	ix := new(big.Int).Mod(new(big.Int).Mul(X, sqrtM1), p)
+	iy := new(big.Int).Mod(new(big.Int).Mul(Y, sqrtM1), p)
+	enableRotate := isNegative(new(big.Int).Mul(T, zInv))
+
+	enchantedDen := new(big.Int).Mod(new(big.Int).Mul(den1, invSqrtAMinusD), p)
+
+	rotX, rotY, rotDen := X, Y, den2
+	if enableRotate {
+		rotX, rotY, rotDen = iy, ix, enchantedDen
+	}
+
+	if isNegative(new(big.Int).Mul(rotX, zInv)) {
+		rotY = new(big.Int).Mod(new(big.Int).Neg(rotY), p)
+	}
+
+	s := new(big.Int).Mod(new(big.Int).Mul(rotDen, new(big.Int).Sub(Z, rotY)), p)
+	s = absBig(s)
+
+	var out [32]byte
+	bigToLEBytes(s, out[:])
+	return out[:]
+}
+
+// Decode parses the canonical 32-byte Ristretto255 encoding in b,
+// rejecting non-canonical inputs by checking that re-encoding the
+// decoded point reproduces exactly b.
+func (P *Point) Decode(b []byte) error {
+	if len(b) != 32 {
+		return errors.New("invalid Ristretto255 encoding length")
+	}
+
+	s := leBytesToBig(b)
+	if s.Cmp(p) >= 0 || isNegative(s) {
+		return errors.New("non-canonical Ristretto255 encoding")
+	}
+
+	ss := new(big.Int).Mod(new(big.Int).Mul(s, s), p)
+	u1 := new(big.Int).Mod(new(big.Int).Sub(big.NewInt(1), ss), p)
+	u2 := new(big.Int).Mod(new(big.Int).Add(big.NewInt(1), ss), p)
+	u2Sqr := new(big.Int).Mod(new(big.Int).Mul(u2, u2), p)
+
+	u1Sqr := new(big.Int).Mod(new(big.Int).Mul(u1, u1), p)
+	v := new(big.Int).Mod(new(big.Int).Sub(
+		new(big.Int).Neg(new(big.Int).Mul(edwardsD, u1Sqr)), u2Sqr), p)
+
+	invsqrt, wasSquare := sqrtRatioM1(big.NewInt(1), new(big.Int).Mul(v, u2Sqr))
+	if !wasSquare {
+		return errors.New("invalid Ristretto255 encoding: not on curve")
+	}
+
+	denX := new(big.Int).Mod(new(big.Int).Mul(invsqrt, u2), p)
+	denY := new(big.Int).Mod(new(big.Int).Mul(new(big.Int).Mul(invsqrt, denX), v), p)
+
+	x := absBig(new(big.Int).Mod(new(big.Int).Mul(
+		new(big.Int).Mul(big.NewInt(2), s), denX), p))
+	y := new(big.Int).Mod(new(big.Int).Mul(u1, denY), p)
+	t := new(big.Int).Mod(new(big.Int).Mul(x, y), p)
+
+	if y.Sign() == 0 || isNegative(t) {
+		return errors.New("invalid Ristretto255 encoding: not on curve")
+	}
+
+	P.ge = groupElement{
+		X: feFromBig(x),
+		Y: feFromBig(y),
+		Z: ed25519.FieldElement{1},
+		T: feFromBig(t),
+	}
+
+	if !bytesEqual(P.Encode(), b) {
+		return errors.New("non-canonical Ristretto255 encoding")
+	}
+	return nil
+}
+
+func bytesEqual(a, b []byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal compares two points by cross-multiplying their extended
+// coordinates, X1*Y2 == Y1*X2 or Y1*Y2 == X1*X2, without normalizing:
+// Ristretto255 points may have distinct representatives for the same
+// group element, and this test holds for any valid pair of them.
+func (P *Point) Equal(P2 crypto.Point) bool {
+	Q := P2.(*Point)
+
+	var x1y2, y1x2, y1y2, x1x2 ed25519.FieldElement
+	ed25519.FeMul(&x1y2, &P.ge.X, &Q.ge.Y)
+	ed25519.FeMul(&y1x2, &P.ge.Y, &Q.ge.X)
+	ed25519.FeMul(&y1y2, &P.ge.Y, &Q.ge.Y)
+	ed25519.FeMul(&x1x2, &P.ge.X, &Q.ge.X)
+
+	var b1, b2 [32]byte
+	ed25519.FeToBytes(&b1, &x1y2)
+	ed25519.FeToBytes(&b2, &y1x2)
+	if bytesEqual(b1[:], b2[:]) {
+		return true
+	}
+
+	ed25519.FeToBytes(&b1, &y1y2)
+	ed25519.FeToBytes(&b2, &x1x2)
+	return bytesEqual(b1[:], b2[:])
+}
+
+func (P *Point) Set(P2 crypto.Point) crypto.Point {
+	P.ge = P2.(*Point).ge
+	return P
+}
+
+func (P *Point) Null() crypto.Point {
+	P.ge = geIdentity()
+	return P
+}
+
+// Base sets P to the standard Ristretto255 base point. Since this
+// group has cofactor 1, Base(rand) simply picks a uniform random point
+// rather than retrying a cofactor-multiplied candidate.
+func (P *Point) Base(rand cipher.Stream) crypto.Point {
+	if rand == nil {
+		P.ge = ristrettoBase
+	} else {
+		P.Pick(nil, rand)
+	}
+	return P
+}
+
+func (P *Point) PickLen() int {
+	return (255 - 8 - 8) / 8
+}
+
+// Pick maps random bytes (and optional embedded data) onto a group
+// element, by repeatedly trying candidate 32-byte strings as a
+// Ristretto255 encoding until one decodes: since Decode's canonical
+// check accepts only a fraction of byte strings, this is a
+// rejection-sampling loop, the same approach the plain Ed25519 point's
+// Pick uses for its own (non-prime-order) encoding. Because a
+// successful Decode's canonical check guarantees Encode reproduces the
+// input bytes exactly, Data can later recover the embedded prefix by
+// re-encoding the point.
+func (P *Point) Pick(data []byte, rand cipher.Stream) (crypto.Point, []byte) {
+	dl := P.PickLen()
+	if dl > len(data) {
+		dl = len(data)
+	}
+
+	for {
+		var b [32]byte
+		rand.XORKeyStream(b[:], b[:])
+		b[31] &= 0x7f // clear bit 255, or half of all candidates are >= p
+		if data != nil {
+			// Length in the high 7 bits of b[0]: bit 0 must stay clear,
+			// since Decode rejects an odd (negative) s.
+			b[0] = byte(dl << 1)
+			copy(b[1:1+dl], data)
+		}
+		if P.Decode(b[:]) == nil {
+			return P, data[dl:]
+		}
+	}
+}
+
+// Data extracts the embedded data from a point produced by Pick.
+func (P *Point) Data() ([]byte, error) {
+	b := P.Encode()
+	dl := int(b[0] >> 1)
+	if dl > P.PickLen() {
+		return nil, errors.New("invalid embedded data length")
+	}
+	return b[1 : 1+dl], nil
+}
+
+func (P *Point) Add(P1, P2 crypto.Point) crypto.Point {
+	a := P1.(*Point)
+	b := P2.(*Point)
+	P.ge = geAdd(&a.ge, &b.ge)
+	return P
+}
+
+func (P *Point) Sub(P1, P2 crypto.Point) crypto.Point {
+	a := P1.(*Point)
+	b := P2.(*Point)
+	nb := geNeg(&b.ge)
+	P.ge = geAdd(&a.ge, &nb)
+	return P
+}
+
+func (P *Point) Neg(A crypto.Point) crypto.Point {
+	P.ge = geNeg(&A.(*Point).ge)
+	return P
+}
+
+func (P *Point) Mul(A crypto.Point, s crypto.Secret) crypto.Point {
+	sb := s.(*crypto.ModInt).V
+	if A == nil {
+		P.ge = geMul(&ristrettoBase, &sb)
+	} else {
+		P.ge = geMul(&A.(*Point).ge, &sb)
+	}
+	return P
+}
+
+// ristrettoBaseX, ristrettoBaseY are the affine coordinates of the
+// standard Ristretto255 base point.
+var ristrettoBaseX = mustBig("15112221349535400772501151409588531511454012693041857206046113283949847762202")
+var ristrettoBaseY = mustBig("46316835694926478169428394003475163141307993866256225615783033603165251855960")
+
+var ristrettoBase groupElement
+
+func init() {
+	ristrettoBase = groupElement{
+		X: feFromBig(ristrettoBaseX),
+		Y: feFromBig(ristrettoBaseY),
+		Z: ed25519.FieldElement{1},
+	}
+	ed25519.FeMul(&ristrettoBase.T, &ristrettoBase.X, &ristrettoBase.Y)
+}
+
+func mustBig(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("ristretto255: bad constant " + s)
+	}
+	return n
+}
+
+// Curve represents the Ristretto255 group.
+type Curve struct {
+}
+
+func (c *Curve) String() string {
+	return "Ristretto255"
+}
+
+func (c *Curve) SecretLen() int {
+	return 32
+}
+
+func (c *Curve) Secret() crypto.Secret {
+	return crypto.NewModInt(0, order)
+}
+
+func (c *Curve) PointLen() int {
+	return 32
+}
+
+func (c *Curve) Point() crypto.Point {
+	return new(Point)
+}
+
+type suite struct {
+	Curve
+}
+
+func (s *suite) HashLen() int { return sha256.Size }
+func (s *suite) Hash() hash.Hash {
+	return sha256.New()
+}
+
+func (s *suite) KeyLen() int { return 16 }
+func (s *suite) Stream(key []byte) cipher.Stream {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic("can't instantiate AES: " + err.Error())
+	}
+	iv := make([]byte, 16)
+	return cipher.NewCTR(block, iv)
+}
+
+// newAES128SHA256Ristretto255 is a ciphersuite based on AES-128,
+// SHA-256, and the Ristretto255 group, paralleling the existing
+// Ed25519 suite but with prime-order (cofactor 1) point semantics.
+func newAES128SHA256Ristretto255() crypto.Suite {
+	return new(suite)
+}
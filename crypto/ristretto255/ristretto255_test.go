@@ -0,0 +1,113 @@
+package ristretto255
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"testing"
+)
+
+// newTestStream returns a deterministic cipher.Stream keyed by seed, so
+// that Pick's randomness is reproducible in these tests.
+func newTestStream(seed byte) cipher.Stream {
+	key := make([]byte, 16)
+	key[0] = seed
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic(err)
+	}
+	return cipher.NewCTR(block, make([]byte, 16))
+}
+
+// basepointMultiples gives the canonical encodings of i·B for i=0..4,
+// taken from an independent Ristretto255 implementation's known-answer
+// test vectors, to check this package's Encode against a second source.
+var basepointMultiples = []string{
+	"0000000000000000000000000000000000000000000000000000000000000000",
+	"e2f2ae0a6abc4e71a884a961c500515f58e30b6aa582dd8db6a65945e08d2d76",
+	"6a493210f7499cd17fecb510ae0cea23a110e8d5b901f8acadd3095c73a3b919",
+	"94741f5d5d52755ece4f23f044ee27d5d1ea1e2bd196b462166b16152a9d0259",
+	"da80862773358b466ffadfe0b3293ab3d9fd53c5ea6c955358f568322daf6a57",
+}
+
+func TestEncodeKnownMultiplesOfBase(t *testing.T) {
+	var sum Point
+	sum.Null()
+
+	var B Point
+	B.Base(nil)
+
+	for i, want := range basepointMultiples {
+		if i > 0 {
+			sum.Add(&sum, &B)
+		}
+
+		wantBytes, err := hex.DecodeString(want)
+		if err != nil {
+			t.Fatalf("bad test vector for %d·B: %v", i, err)
+		}
+		if got := sum.Encode(); hex.EncodeToString(got) != hex.EncodeToString(wantBytes) {
+			t.Fatalf("%d·B encoded to %x, want %x", i, got, wantBytes)
+		}
+	}
+}
+
+// TestEncodeDecodeRoundTrip checks that decoding the encoding of a
+// point recovers an equal point, for the identity, the base point, and
+// a handful of its multiples.
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	var B Point
+	B.Base(nil)
+
+	var cur Point
+	cur.Null()
+
+	for i := 0; i < 8; i++ {
+		enc := cur.Encode()
+
+		var decoded Point
+		if err := decoded.Decode(enc); err != nil {
+			t.Fatalf("%d·B: Decode failed: %v", i, err)
+		}
+		if !decoded.Equal(&cur) {
+			t.Fatalf("%d·B: decoded point does not equal original", i)
+		}
+
+		cur.Add(&cur, &B)
+	}
+}
+
+// TestDecodeRejectsNonCanonical checks that Decode rejects an encoding
+// whose integer value is >= p, which can never be produced by Encode.
+func TestDecodeRejectsNonCanonical(t *testing.T) {
+	b := make([]byte, 32)
+	for i := range b {
+		b[i] = 0xff
+	}
+
+	var P Point
+	if err := P.Decode(b); err == nil {
+		t.Fatal("Decode accepted a non-canonical encoding")
+	}
+}
+
+// TestPickDataRoundTrip checks that data embedded via Pick is recovered
+// unchanged by Data.
+func TestPickDataRoundTrip(t *testing.T) {
+	want := []byte("ristretto255 pick/data test")
+
+	var P Point
+	_, remainder := P.Pick(want, newTestStream(1))
+	if len(remainder) != 0 {
+		t.Fatalf("unexpected leftover data: %q", remainder)
+	}
+
+	got, err := P.Data()
+	if err != nil {
+		t.Fatalf("Data failed: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("Data() = %q, want %q", got, want)
+	}
+}